@@ -0,0 +1,67 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+const (
+	// GCPServiceAccountSecretAnnotationPrefix is the prefix of the annotation that specifies
+	// the name of the secret holding a GCP service account key and the path to mount it at,
+	// e.g. sparkoperator.k8s.io/gcp-service-account-secret.<secretName>=<mountPath>.
+	GCPServiceAccountSecretAnnotationPrefix = "sparkoperator.k8s.io/gcp-service-account-secret."
+	// GeneralSecretsAnnotationPrefix is the prefix of the annotation that specifies the name
+	// of a general-purpose secret and the path to mount it at,
+	// e.g. sparkoperator.k8s.io/secret.<secretName>=<mountPath>.
+	GeneralSecretsAnnotationPrefix = "sparkoperator.k8s.io/secret."
+
+	// AWSCredentialsSecretAnnotationPrefix is the prefix of the annotation that specifies
+	// the name of the secret holding AWS credentials and the path to mount it at,
+	// e.g. sparkoperator.k8s.io/aws-credentials-secret.<secretName>=<mountPath>.
+	AWSCredentialsSecretAnnotationPrefix = "sparkoperator.k8s.io/aws-credentials-secret."
+	// AzureCredentialsSecretAnnotationPrefix is the prefix of the annotation that specifies
+	// the name of the secret holding Azure credentials and the path to mount it at,
+	// e.g. sparkoperator.k8s.io/azure-credentials-secret.<secretName>=<mountPath>.
+	AzureCredentialsSecretAnnotationPrefix = "sparkoperator.k8s.io/azure-credentials-secret."
+
+	// SecretManagerBackendAnnotation specifies which external secret manager backend to
+	// fetch secrets from: one of "vault", "aws-sm" or "gcp-sm".
+	SecretManagerBackendAnnotation = "sparkoperator.k8s.io/secret-manager.backend"
+	// SecretManagerSidecarImageAnnotation overrides the default sidecar/init container
+	// image for the selected secret manager backend.
+	SecretManagerSidecarImageAnnotation = "sparkoperator.k8s.io/secret-manager.sidecar-image"
+	// SecretManagerMountPathAnnotation overrides the default path the shared secret
+	// manager volume is mounted at.
+	SecretManagerMountPathAnnotation = "sparkoperator.k8s.io/secret-manager.mount-path"
+	// SecretManagerVaultRoleAnnotation specifies the Vault Kubernetes auth role to log in
+	// as. Only used when SecretManagerBackendAnnotation is "vault".
+	SecretManagerVaultRoleAnnotation = "sparkoperator.k8s.io/secret-manager.vault-role"
+	// SecretManagerGCPServiceAccountKeySecretIDAnnotation specifies a GCP Secret Manager
+	// secret holding a Google service account key, for which GOOGLE_APPLICATION_CREDENTIALS
+	// is also set. Only used when SecretManagerBackendAnnotation is "gcp-sm".
+	SecretManagerGCPServiceAccountKeySecretIDAnnotation = "sparkoperator.k8s.io/secret-manager.gcp-service-account-key-secret-id"
+	// SecretManagerSecretIDsAnnotation is a comma-separated list of backend-specific
+	// secret identifiers to fetch.
+	SecretManagerSecretIDsAnnotation = "sparkoperator.k8s.io/secret-manager.secret-ids"
+
+	// ProjectedServiceAccountTokenAnnotationPrefix is the prefix of the annotation that
+	// requests a projected, bound service account token for the given audience and the
+	// path to mount it at, e.g. sparkoperator.k8s.io/projected-sa-token.<audience>=<mountPath>.
+	ProjectedServiceAccountTokenAnnotationPrefix = "sparkoperator.k8s.io/projected-sa-token."
+
+	// GCPActivateServiceAccountAnnotation requests that the GCP service account secret
+	// mounted on the pod be activated via `gcloud auth activate-service-account`, so
+	// gsutil/bq/gcloud invocations inherit it automatically. Must be set to "true".
+	GCPActivateServiceAccountAnnotation = "sparkoperator.k8s.io/gcp-activate-service-account"
+)
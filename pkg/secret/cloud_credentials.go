@@ -0,0 +1,152 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	// AWSSharedCredentialsFileEnvVar is the environment variable the AWS SDKs read to
+	// locate an ini-format shared credentials file.
+	AWSSharedCredentialsFileEnvVar = "AWS_SHARED_CREDENTIALS_FILE"
+	// AWSCredentialsFileName is the default name of the shared credentials file inside
+	// the mounted secret.
+	AWSCredentialsFileName = "credentials"
+	// AWSCredentialsSecretVolumeName is the name of the AWS credentials secret volume.
+	AWSCredentialsSecretVolumeName = "aws-credentials-secret-volume"
+
+	// AzureAuthLocationEnvVar is the environment variable read by
+	// auth.NewAuthorizerFromEnvironment to locate a JSON file holding a service
+	// principal's clientId/clientSecret/tenantId/subscriptionId.
+	AzureAuthLocationEnvVar = "AZURE_AUTH_LOCATION"
+	// AzureAuthFileName is the default name of the JSON auth file inside the mounted secret.
+	AzureAuthFileName = "azure.json"
+	// AzureCredentialsSecretVolumeName is the name of the Azure credentials secret volume.
+	AzureCredentialsSecretVolumeName = "azure-credentials-secret-volume"
+)
+
+// CloudCredentialInjector abstracts over the cloud-provider-specific work of
+// finding a credentials secret annotation and wiring the resulting volume
+// mount into a driver or executor container. This lets the mutating webhook
+// treat GCP, AWS and Azure identically instead of special-casing GCP.
+type CloudCredentialInjector interface {
+	// FindSecret looks for this provider's credentials secret annotation and
+	// returns the secret name and mount path if the annotation is present.
+	FindSecret(annotations map[string]string) (name string, mountPath string, found bool)
+	// Inject mounts the secret volume with volumeName onto mountPath into
+	// container and sets the provider's canonical environment variables so
+	// client libraries pick up the credentials without further configuration.
+	Inject(volumeName string, mountPath string, container *v1.Container)
+	// VolumeName returns the name of the pod volume this injector mounts its secret
+	// from, so callers can add the volume once per pod via AddSecretVolumeToPod before
+	// calling Inject for each container.
+	VolumeName() string
+}
+
+// CloudCredentialInjectors lists the injectors the mutating webhook should
+// consult, in the order their annotations are checked.
+var CloudCredentialInjectors = []CloudCredentialInjector{
+	GCPCredentialInjector{},
+	AWSCredentialInjector{},
+	AzureCredentialInjector{},
+}
+
+// GCPCredentialInjector wires up a GCP service account secret so
+// GOOGLE_APPLICATION_CREDENTIALS points at the mounted key file, mirroring
+// FindGCPServiceAccountSecret and MountServiceAccountSecretToContainer.
+type GCPCredentialInjector struct{}
+
+// FindSecret implements CloudCredentialInjector.
+func (i GCPCredentialInjector) FindSecret(annotations map[string]string) (string, string, bool) {
+	return FindGCPServiceAccountSecret(annotations)
+}
+
+// Inject implements CloudCredentialInjector.
+func (i GCPCredentialInjector) Inject(volumeName string, mountPath string, container *v1.Container) {
+	MountSecretToContainer(volumeName, mountPath, container)
+	jsonKeyFilePath := fmt.Sprintf("%s/%s", mountPath, ServiceAccountJSONKeyFileName)
+	setContainerEnvVarOnce(container, v1.EnvVar{Name: GoogleApplicationCredentialsEnvVar, Value: jsonKeyFilePath})
+}
+
+// VolumeName implements CloudCredentialInjector.
+func (i GCPCredentialInjector) VolumeName() string {
+	return ServiceAccountSecretVolumeName
+}
+
+// AWSCredentialInjector wires up an AWS credentials secret containing an ini-format
+// shared credentials file into AWS_SHARED_CREDENTIALS_FILE, rather than setting
+// AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY directly: unlike GOOGLE_APPLICATION_CREDENTIALS,
+// those must be the literal credential values, not a path to a mounted file.
+type AWSCredentialInjector struct{}
+
+// FindSecret implements CloudCredentialInjector.
+func (i AWSCredentialInjector) FindSecret(annotations map[string]string) (string, string, bool) {
+	for annotation := range annotations {
+		if strings.HasPrefix(annotation, config.AWSCredentialsSecretAnnotationPrefix) {
+			name := strings.TrimPrefix(annotation, config.AWSCredentialsSecretAnnotationPrefix)
+			return name, annotations[annotation], true
+		}
+	}
+	return "", "", false
+}
+
+// Inject implements CloudCredentialInjector.
+func (i AWSCredentialInjector) Inject(volumeName string, mountPath string, container *v1.Container) {
+	MountSecretToContainer(volumeName, mountPath, container)
+	credentialsFilePath := fmt.Sprintf("%s/%s", mountPath, AWSCredentialsFileName)
+	setContainerEnvVarOnce(container, v1.EnvVar{Name: AWSSharedCredentialsFileEnvVar, Value: credentialsFilePath})
+}
+
+// VolumeName implements CloudCredentialInjector.
+func (i AWSCredentialInjector) VolumeName() string {
+	return AWSCredentialsSecretVolumeName
+}
+
+// AzureCredentialInjector wires up an Azure credentials secret containing a JSON service
+// principal auth file into AZURE_AUTH_LOCATION (read by auth.NewAuthorizerFromEnvironment,
+// github.com/Azure/go-autorest/autorest/azure/auth), rather than setting the ARM_* vars
+// directly, for the same reason as AWSCredentialInjector.
+type AzureCredentialInjector struct{}
+
+// FindSecret implements CloudCredentialInjector.
+func (i AzureCredentialInjector) FindSecret(annotations map[string]string) (string, string, bool) {
+	for annotation := range annotations {
+		if strings.HasPrefix(annotation, config.AzureCredentialsSecretAnnotationPrefix) {
+			name := strings.TrimPrefix(annotation, config.AzureCredentialsSecretAnnotationPrefix)
+			return name, annotations[annotation], true
+		}
+	}
+	return "", "", false
+}
+
+// Inject implements CloudCredentialInjector.
+func (i AzureCredentialInjector) Inject(volumeName string, mountPath string, container *v1.Container) {
+	MountSecretToContainer(volumeName, mountPath, container)
+	authFilePath := fmt.Sprintf("%s/%s", mountPath, AzureAuthFileName)
+	setContainerEnvVarOnce(container, v1.EnvVar{Name: AzureAuthLocationEnvVar, Value: authFilePath})
+}
+
+// VolumeName implements CloudCredentialInjector.
+func (i AzureCredentialInjector) VolumeName() string {
+	return AzureCredentialsSecretVolumeName
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+func TestCloudCredentialInjectors_FindSecret(t *testing.T) {
+	tests := []struct {
+		name        string
+		injector    CloudCredentialInjector
+		annotations map[string]string
+		wantName    string
+		wantPath    string
+		wantFound   bool
+	}{
+		{
+			name:        "gcp found",
+			injector:    GCPCredentialInjector{},
+			annotations: map[string]string{config.GCPServiceAccountSecretAnnotationPrefix + "my-secret": "/mnt/gcp"},
+			wantName:    "my-secret",
+			wantPath:    "/mnt/gcp",
+			wantFound:   true,
+		},
+		{
+			name:        "gcp not found",
+			injector:    GCPCredentialInjector{},
+			annotations: map[string]string{},
+			wantFound:   false,
+		},
+		{
+			name:        "aws found",
+			injector:    AWSCredentialInjector{},
+			annotations: map[string]string{config.AWSCredentialsSecretAnnotationPrefix + "my-secret": "/mnt/aws"},
+			wantName:    "my-secret",
+			wantPath:    "/mnt/aws",
+			wantFound:   true,
+		},
+		{
+			name:        "azure found",
+			injector:    AzureCredentialInjector{},
+			annotations: map[string]string{config.AzureCredentialsSecretAnnotationPrefix + "my-secret": "/mnt/azure"},
+			wantName:    "my-secret",
+			wantPath:    "/mnt/azure",
+			wantFound:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, path, found := test.injector.FindSecret(test.annotations)
+			assert.Equal(t, test.wantFound, found)
+			if test.wantFound {
+				assert.Equal(t, test.wantName, name)
+				assert.Equal(t, test.wantPath, path)
+			}
+		})
+	}
+}
+
+func TestCloudCredentialInjectors_Inject(t *testing.T) {
+	tests := []struct {
+		name       string
+		injector   CloudCredentialInjector
+		wantEnvVar string
+	}{
+		{name: "gcp", injector: GCPCredentialInjector{}, wantEnvVar: GoogleApplicationCredentialsEnvVar},
+		{name: "aws", injector: AWSCredentialInjector{}, wantEnvVar: AWSSharedCredentialsFileEnvVar},
+		{name: "azure", injector: AzureCredentialInjector{}, wantEnvVar: AzureAuthLocationEnvVar},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container := &v1.Container{}
+
+			test.injector.Inject(test.injector.VolumeName(), "/mnt/creds", container)
+			assert.Len(t, container.VolumeMounts, 1)
+			assert.Len(t, container.Env, 1)
+			assert.Equal(t, test.wantEnvVar, container.Env[0].Name)
+
+			// A second Inject call, as happens on a repeated webhook admission pass
+			// over the same pod, must not duplicate the volume mount or env var.
+			test.injector.Inject(test.injector.VolumeName(), "/mnt/creds", container)
+			assert.Len(t, container.VolumeMounts, 1)
+			assert.Len(t, container.Env, 1)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+const (
+	// CloudSDKConfigVolumeName is the name of the emptyDir volume shared between
+	// the gcloud auth init container and the driver, so the activated account
+	// set up by the former is visible to gcloud/gsutil/bq invocations in the latter.
+	CloudSDKConfigVolumeName = "gcloud-config"
+	// CloudSDKConfigMountPath is the path CloudSDKConfigVolumeName is mounted at,
+	// matching the CLOUDSDK_CONFIG environment variable read by the Cloud SDK.
+	CloudSDKConfigMountPath = "/etc/gcloud"
+	// CloudSDKConfigEnvVar is the environment variable the Cloud SDK uses to
+	// locate its configuration directory.
+	CloudSDKConfigEnvVar = "CLOUDSDK_CONFIG"
+	// GCloudAuthInitContainerName is the name of the init container that
+	// activates the mounted GCP service account.
+	GCloudAuthInitContainerName = "gcloud-auth-init"
+	// DefaultGCloudAuthInitImage is the default image used to run
+	// `gcloud auth activate-service-account` when no operator flag overrides it.
+	DefaultGCloudAuthInitImage = "google/cloud-sdk:slim"
+)
+
+// ShouldActivateGCloudServiceAccount reports whether annotations request that the GCP
+// service account mounted on the pod be activated via gcloud, i.e. the
+// sparkoperator.k8s.io/gcp-activate-service-account annotation is present and set to "true".
+func ShouldActivateGCloudServiceAccount(annotations map[string]string) bool {
+	return annotations[config.GCPActivateServiceAccountAnnotation] == "true"
+}
+
+// InjectGCloudAuthInitContainer appends an init container to pod that runs `gcloud auth
+// activate-service-account` against the GCP service account key found in secretName (the
+// same secret mounted into containers by MountServiceAccountSecretToContainer at
+// serviceAccountSecretMountPath), and adds a shared CLOUDSDK_CONFIG emptyDir volume so the
+// activated account carries over into container. This lets Spark jobs that shell out to
+// gsutil/bq/gcloud, rather than relying on ADC library discovery alone, inherit the active
+// account. It is appended rather than prepended because it has no data dependency on any
+// other init container (e.g. a secret-manager-sidecar fetch container) that may already be
+// on pod; if a future caller needs gcloud activation to run before another init container,
+// that ordering must be established by the caller.
+//
+// The init container mounts the GCP service account secret volume itself, so it does not
+// depend on MountServiceAccountSecretToContainer having already been called on container.
+// It is idempotent: creating the init container itself happens at most once per pod, and
+// wiring container into the shared CLOUDSDK_CONFIG volume is independently idempotent via
+// MountSecretToContainer, so calling this once per container on a multi-container pod (as
+// the pod mutator does) wires up every container rather than only the first.
+func InjectGCloudAuthInitContainer(pod *v1.Pod, container *v1.Container, secretName string, serviceAccountSecretMountPath string, image string) {
+	addEmptyDirVolumeToPod(CloudSDKConfigVolumeName, pod)
+	cloudSDKConfigEnvVar := v1.EnvVar{Name: CloudSDKConfigEnvVar, Value: CloudSDKConfigMountPath}
+
+	alreadyInjected := false
+	for _, initContainer := range pod.Spec.InitContainers {
+		if initContainer.Name == GCloudAuthInitContainerName {
+			alreadyInjected = true
+			break
+		}
+	}
+
+	if !alreadyInjected {
+		if image == "" {
+			image = DefaultGCloudAuthInitImage
+		}
+
+		AddSecretVolumeToPod(ServiceAccountSecretVolumeName, secretName, pod)
+		serviceAccountKeyFilePath := fmt.Sprintf("%s/%s", serviceAccountSecretMountPath, ServiceAccountJSONKeyFileName)
+
+		initContainer := v1.Container{
+			Name:  GCloudAuthInitContainerName,
+			Image: image,
+			Command: []string{
+				"gcloud", "auth", "activate-service-account", fmt.Sprintf("--key-file=%s", serviceAccountKeyFilePath),
+			},
+			VolumeMounts: []v1.VolumeMount{
+				{Name: ServiceAccountSecretVolumeName, ReadOnly: true, MountPath: serviceAccountSecretMountPath},
+				{Name: CloudSDKConfigVolumeName, MountPath: CloudSDKConfigMountPath},
+			},
+			Env: []v1.EnvVar{cloudSDKConfigEnvVar},
+		}
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+	}
+
+	MountSecretToContainer(CloudSDKConfigVolumeName, CloudSDKConfigMountPath, container)
+	setContainerEnvVarOnce(container, cloudSDKConfigEnvVar)
+}
+
+// addEmptyDirVolumeToPod adds an emptyDir volume named volumeName to pod. Like
+// AddSecretVolumeToPod, it is idempotent: if a volume named volumeName already exists on
+// pod, it leaves pod unchanged.
+func addEmptyDirVolumeToPod(volumeName string, pod *v1.Pod) {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == volumeName {
+			return
+		}
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name:         volumeName,
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	})
+}
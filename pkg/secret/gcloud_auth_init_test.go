@@ -0,0 +1,59 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+func TestShouldActivateGCloudServiceAccount(t *testing.T) {
+	assert.True(t, ShouldActivateGCloudServiceAccount(map[string]string{config.GCPActivateServiceAccountAnnotation: "true"}))
+	assert.False(t, ShouldActivateGCloudServiceAccount(map[string]string{config.GCPActivateServiceAccountAnnotation: "false"}))
+	assert.False(t, ShouldActivateGCloudServiceAccount(map[string]string{}))
+}
+
+func TestInjectGCloudAuthInitContainer_MultiContainerPod(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver"}, {Name: "sidecar"}}}}
+
+	for i := range pod.Spec.Containers {
+		InjectGCloudAuthInitContainer(pod, &pod.Spec.Containers[i], "my-secret", "/mnt/gcp", "")
+	}
+
+	assert.Len(t, pod.Spec.InitContainers, 1, "the init container itself must only be added once")
+	for _, container := range pod.Spec.Containers {
+		assert.Len(t, container.VolumeMounts, 1, "every container must have CLOUDSDK_CONFIG mounted, not just the first")
+		assert.Len(t, container.Env, 1)
+		assert.Equal(t, CloudSDKConfigEnvVar, container.Env[0].Name)
+	}
+}
+
+func TestInjectGCloudAuthInitContainer_DoubleInvocationIsIdempotent(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver"}}}}
+	container := &pod.Spec.Containers[0]
+
+	InjectGCloudAuthInitContainer(pod, container, "my-secret", "/mnt/gcp", "")
+	InjectGCloudAuthInitContainer(pod, container, "my-secret", "/mnt/gcp", "")
+
+	assert.Len(t, pod.Spec.InitContainers, 1)
+	assert.Len(t, container.VolumeMounts, 1)
+	assert.Len(t, container.Env, 1)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+// ProjectedServiceAccountTokenPath is the default file name a projected
+// service account token is written under within its mount path.
+const ProjectedServiceAccountTokenPath = "token"
+
+// DefaultProjectedServiceAccountTokenExpirationSeconds is the token TTL used when the
+// sparkoperator.k8s.io/projected-sa-token.<audience> annotation doesn't carry an explicit
+// expiration, matching the default the Kubernetes API server itself uses for projected
+// service account tokens.
+const DefaultProjectedServiceAccountTokenExpirationSeconds int64 = 3600
+
+// FindProjectedServiceAccountTokens finds the annotations for specifying
+// projected service account tokens and returns a map of requested audience
+// to mount path. Annotations are of the form
+// sparkoperator.k8s.io/projected-sa-token.<audience>=<mountPath>.
+func FindProjectedServiceAccountTokens(annotations map[string]string) map[string]string {
+	tokens := make(map[string]string)
+	for annotation := range annotations {
+		if strings.HasPrefix(annotation, config.ProjectedServiceAccountTokenAnnotationPrefix) {
+			audience := strings.TrimPrefix(annotation, config.ProjectedServiceAccountTokenAnnotationPrefix)
+			tokens[audience] = annotations[annotation]
+		}
+	}
+	return tokens
+}
+
+// AddProjectedServiceAccountTokenToPod adds a projected volume carrying a
+// bound service account token for audience, valid for expirationSeconds, to
+// pod, and mounts it at mountPath in every container. It is idempotent: if a
+// projected volume for the same audience already exists on pod, it returns
+// without making changes.
+func AddProjectedServiceAccountTokenToPod(pod *v1.Pod, audience string, expirationSeconds int64, mountPath string) {
+	volumeName := projectedServiceAccountTokenVolumeName(audience)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == volumeName {
+			return
+		}
+	}
+
+	volume := v1.Volume{
+		Name: volumeName,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{
+				Sources: []v1.VolumeProjection{
+					{
+						ServiceAccountToken: &v1.ServiceAccountTokenProjection{
+							Audience:          audience,
+							ExpirationSeconds: &expirationSeconds,
+							Path:              ProjectedServiceAccountTokenPath,
+						},
+					},
+				},
+			},
+		},
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
+
+	volumeMount := v1.VolumeMount{Name: volumeName, ReadOnly: true, MountPath: mountPath}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, volumeMount)
+	}
+}
+
+// projectedServiceAccountTokenVolumeName derives a unique, deterministic volume name
+// for a projected service account token volume serving audience. Audiences are
+// arbitrary strings (e.g. GKE Workload Identity provider URLs) that can be far longer
+// than the 63-character DNS-1123 label limit Kubernetes enforces on volume names, and
+// can contain characters beyond [a-z0-9-], so the audience is hashed rather than
+// sanitized in place to guarantee a short, always-valid name.
+func projectedServiceAccountTokenVolumeName(audience string) string {
+	sum := sha256.Sum256([]byte(audience))
+	return fmt.Sprintf("projected-sa-token-%s", hex.EncodeToString(sum[:])[:16])
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+)
+
+var dns1123LabelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func TestProjectedServiceAccountTokenVolumeName_ValidDNS1123Label(t *testing.T) {
+	audiences := []string{
+		"sts.amazonaws.com",
+		"https://container.googleapis.com/v1/projects/my-project/locations/us-central1/clusters/my-cluster",
+		strings.Repeat("a", 500),
+		"audience/with:special.chars_and_UPPER",
+	}
+
+	for _, audience := range audiences {
+		name := projectedServiceAccountTokenVolumeName(audience)
+		assert.LessOrEqual(t, len(name), 63)
+		assert.True(t, dns1123LabelRegexp.MatchString(name), "volume name %q for audience %q is not a valid DNS-1123 label", name, audience)
+	}
+}
+
+func TestProjectedServiceAccountTokenVolumeName_DeterministicAndUnique(t *testing.T) {
+	assert.Equal(t, projectedServiceAccountTokenVolumeName("aud-a"), projectedServiceAccountTokenVolumeName("aud-a"))
+	assert.NotEqual(t, projectedServiceAccountTokenVolumeName("aud-a"), projectedServiceAccountTokenVolumeName("aud-b"))
+}
+
+func TestAddProjectedServiceAccountTokenToPod_DoubleInvocationIsIdempotent(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver"}}}}
+
+	AddProjectedServiceAccountTokenToPod(pod, "aud-a", DefaultProjectedServiceAccountTokenExpirationSeconds, "/mnt/token")
+	AddProjectedServiceAccountTokenToPod(pod, "aud-a", DefaultProjectedServiceAccountTokenExpirationSeconds, "/mnt/token")
+	assert.Len(t, pod.Spec.Volumes, 1)
+	assert.Len(t, pod.Spec.Containers[0].VolumeMounts, 1)
+}
@@ -38,8 +38,16 @@ const (
 	ServiceAccountSecretVolumeName = "gcp-service-account-secret-volume"
 )
 
-// AddSecretVolumeToPod adds a secret volume for the secret with secretName into pod.
+// AddSecretVolumeToPod adds a secret volume for the secret with secretName into pod. It is
+// idempotent: if a volume named secretVolumeName already exists on pod, it leaves pod
+// unchanged instead of appending a duplicate.
 func AddSecretVolumeToPod(secretVolumeName string, secretName string, pod *v1.Pod) {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == secretVolumeName {
+			return
+		}
+	}
+
 	volume := v1.Volume{
 		Name: secretVolumeName,
 		VolumeSource: v1.VolumeSource{
@@ -51,8 +59,16 @@ func AddSecretVolumeToPod(secretVolumeName string, secretName string, pod *v1.Po
 	pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
 }
 
-// MountSecretToContainer mounts the secret volume with volumeName onto the mountPath into container.
+// MountSecretToContainer mounts the secret volume with volumeName onto the mountPath into
+// container. It is idempotent: if container already has a volume mount named volumeName,
+// it leaves container unchanged instead of appending a duplicate.
 func MountSecretToContainer(volumeName string, mountPath string, container *v1.Container) {
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == volumeName {
+			return
+		}
+	}
+
 	volumeMount := v1.VolumeMount{
 		Name:      volumeName,
 		ReadOnly:  true,
@@ -61,6 +77,18 @@ func MountSecretToContainer(volumeName string, mountPath string, container *v1.C
 	container.VolumeMounts = append(container.VolumeMounts, volumeMount)
 }
 
+// setContainerEnvVarOnce sets envVar on container, unless container already has an Env
+// entry with that name, in which case it leaves container unchanged instead of appending
+// a duplicate.
+func setContainerEnvVarOnce(container *v1.Container, envVar v1.EnvVar) {
+	for _, existing := range container.Env {
+		if existing.Name == envVar.Name {
+			return
+		}
+	}
+	container.Env = append(container.Env, envVar)
+}
+
 // FindGCPServiceAccountSecret finds the annotation for specifying GCP service account
 // secret and returns the name and mount path of the secret if the annotation is found.
 func FindGCPServiceAccountSecret(annotations map[string]string) (string, string, bool) {
@@ -95,5 +123,5 @@ func MountServiceAccountSecretToContainer(mountPath string, container *v1.Contai
 	MountSecretToContainer(ServiceAccountSecretVolumeName, mountPath, container)
 	jsonKeyFilePath := fmt.Sprintf("%s/%s", mountPath, ServiceAccountJSONKeyFileName)
 	appCredentialEnvVar := v1.EnvVar{Name: GoogleApplicationCredentialsEnvVar, Value: jsonKeyFilePath}
-	container.Env = append(container.Env, appCredentialEnvVar)
+	setContainerEnvVarOnce(container, appCredentialEnvVar)
 }
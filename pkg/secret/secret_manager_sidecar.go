@@ -0,0 +1,294 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+// SecretManagerBackend identifies the external secret manager a
+// SecretManagerConfig pulls secrets from.
+type SecretManagerBackend string
+
+const (
+	// VaultSecretManager fetches secrets from a HashiCorp Vault KV v2 engine.
+	VaultSecretManager SecretManagerBackend = "vault"
+	// AWSSecretManagerBackend fetches secrets from AWS Secrets Manager.
+	AWSSecretManagerBackend SecretManagerBackend = "aws-sm"
+	// GCPSecretManagerBackend fetches secrets from GCP Secret Manager.
+	GCPSecretManagerBackend SecretManagerBackend = "gcp-sm"
+
+	// SecretManagerVolumeName is the name of the shared in-memory volume that
+	// the secret manager sidecar writes fetched secrets into.
+	SecretManagerVolumeName = "secret-manager-volume"
+	// DefaultSecretManagerMountPath is the default path, in every container,
+	// at which the shared secret manager volume is mounted.
+	DefaultSecretManagerMountPath = "/etc/secret-manager"
+	// DefaultVaultSidecarImage is the default image used for the Vault agent
+	// sidecar when SecretManagerConfig.SidecarImage is unset.
+	DefaultVaultSidecarImage = "vault:1.13"
+	// DefaultAWSSecretManagerSidecarImage is the default image used for the
+	// AWS Secrets Manager init container when SidecarImage is unset.
+	DefaultAWSSecretManagerSidecarImage = "amazon/aws-cli:2.13.0"
+	// DefaultGCPSecretManagerSidecarImage is the default image used for the
+	// GCP Secret Manager init container when SidecarImage is unset.
+	DefaultGCPSecretManagerSidecarImage = "gcr.io/google.com/cloudsdktool/cloud-sdk:slim"
+
+	// gcpSecretManagerKeyFileName is the name under which a Google service
+	// account key fetched from GCP Secret Manager is written, so it lines up
+	// with MountServiceAccountSecretToContainer's GOOGLE_APPLICATION_CREDENTIALS.
+	gcpSecretManagerKeyFileName = "key.json"
+)
+
+// SecretManagerConfig describes how to fetch secrets from an external secret
+// manager into a shared emptyDir volume mounted by every container in the pod,
+// as an alternative to mounting a static Kubernetes Secret.
+type SecretManagerConfig struct {
+	// Backend selects which secret manager to pull from.
+	Backend SecretManagerBackend
+	// SidecarImage overrides the default image for the selected backend.
+	SidecarImage string
+	// Resources are the compute resources requested for the sidecar/init container.
+	Resources v1.ResourceRequirements
+	// MountPath is where the shared volume is mounted in every container.
+	// Defaults to DefaultSecretManagerMountPath.
+	MountPath string
+	// SecretIDs are the backend-specific secret identifiers to fetch (Vault KV
+	// paths, AWS Secrets Manager secret IDs/ARNs, or GCP Secret Manager
+	// resource names), each written to a file of the same basename under MountPath.
+	SecretIDs []string
+	// VaultRole is the Vault Kubernetes auth role to log in as. Only used when
+	// Backend is VaultSecretManager.
+	VaultRole string
+	// GCPServiceAccountKeySecretID is the GCP Secret Manager secret holding a
+	// Google service account key. When set, InjectSecretManagerSidecar also
+	// sets GOOGLE_APPLICATION_CREDENTIALS on every container for parity with
+	// MountServiceAccountSecretToContainer.
+	GCPServiceAccountKeySecretID string
+}
+
+// FindSecretManagerConfig parses the sparkoperator.k8s.io/secret-manager.*
+// annotations into a SecretManagerConfig, returning false if the backend
+// annotation is absent.
+func FindSecretManagerConfig(annotations map[string]string) (*SecretManagerConfig, bool) {
+	backend, ok := annotations[config.SecretManagerBackendAnnotation]
+	if !ok {
+		return nil, false
+	}
+
+	cfg := &SecretManagerConfig{
+		Backend:                      SecretManagerBackend(backend),
+		SidecarImage:                 annotations[config.SecretManagerSidecarImageAnnotation],
+		MountPath:                    annotations[config.SecretManagerMountPathAnnotation],
+		VaultRole:                    annotations[config.SecretManagerVaultRoleAnnotation],
+		GCPServiceAccountKeySecretID: annotations[config.SecretManagerGCPServiceAccountKeySecretIDAnnotation],
+	}
+	if cfg.MountPath == "" {
+		cfg.MountPath = DefaultSecretManagerMountPath
+	}
+	if ids, ok := annotations[config.SecretManagerSecretIDsAnnotation]; ok {
+		for _, id := range strings.Split(ids, ",") {
+			if trimmed := strings.TrimSpace(id); trimmed != "" {
+				cfg.SecretIDs = append(cfg.SecretIDs, trimmed)
+			}
+		}
+	}
+	return cfg, true
+}
+
+// InjectSecretManagerSidecar adds a secret manager sidecar/init container to
+// pod based on the sparkoperator.k8s.io/secret-manager.* annotations found on
+// annotations, along with the shared in-memory volume and matching volume
+// mounts on the fetch container and every pre-existing container. It is a
+// no-op if no secret manager annotation is present, and idempotent: if pod
+// already has a volume named SecretManagerVolumeName, it leaves pod unchanged.
+func InjectSecretManagerSidecar(pod *v1.Pod, annotations map[string]string) error {
+	cfg, ok := FindSecretManagerConfig(annotations)
+	if !ok {
+		return nil
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == SecretManagerVolumeName {
+			return nil
+		}
+	}
+
+	container, asInit, err := buildSecretManagerContainer(cfg)
+	if err != nil {
+		return err
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name: SecretManagerVolumeName,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{
+				Medium: v1.StorageMediumMemory,
+			},
+		},
+	})
+
+	// Mount the shared volume on the fetch container itself, before appending it, so
+	// the init container actually sees somewhere durable to write the secrets it
+	// fetches instead of its own throwaway filesystem.
+	volumeMount := v1.VolumeMount{Name: SecretManagerVolumeName, MountPath: cfg.MountPath}
+	container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+
+	numExistingContainers := len(pod.Spec.Containers)
+	if asInit {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, *container)
+	} else {
+		pod.Spec.Containers = append(pod.Spec.Containers, *container)
+	}
+
+	for i := 0; i < numExistingContainers; i++ {
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, volumeMount)
+	}
+
+	if cfg.Backend == GCPSecretManagerBackend && cfg.GCPServiceAccountKeySecretID != "" {
+		keyFilePath := fmt.Sprintf("%s/%s", cfg.MountPath, gcpSecretManagerKeyFileName)
+		appCredentialEnvVar := v1.EnvVar{Name: GoogleApplicationCredentialsEnvVar, Value: keyFilePath}
+		for i := 0; i < numExistingContainers; i++ {
+			setContainerEnvVarOnce(&pod.Spec.Containers[i], appCredentialEnvVar)
+		}
+	}
+
+	return nil
+}
+
+// buildSecretManagerContainer builds the container that fetches cfg's
+// secrets into the shared volume. All three backends terminate after writing
+// the files, so they all run as init containers.
+func buildSecretManagerContainer(cfg *SecretManagerConfig) (*v1.Container, bool, error) {
+	switch cfg.Backend {
+	case VaultSecretManager:
+		image := cfg.SidecarImage
+		if image == "" {
+			image = DefaultVaultSidecarImage
+		}
+		return &v1.Container{
+			Name:      "vault-secrets-init",
+			Image:     image,
+			Resources: cfg.Resources,
+			Command:   vaultSecretManagerFetchCommand(cfg),
+		}, true, nil
+	case AWSSecretManagerBackend:
+		image := cfg.SidecarImage
+		if image == "" {
+			image = DefaultAWSSecretManagerSidecarImage
+		}
+		return &v1.Container{
+			Name:      "aws-secrets-init",
+			Image:     image,
+			Resources: cfg.Resources,
+			Command:   awsSecretManagerFetchCommand(cfg),
+		}, true, nil
+	case GCPSecretManagerBackend:
+		image := cfg.SidecarImage
+		if image == "" {
+			image = DefaultGCPSecretManagerSidecarImage
+		}
+		return &v1.Container{
+			Name:      "gcp-secrets-init",
+			Image:     image,
+			Resources: cfg.Resources,
+			Command:   gcpSecretManagerFetchCommand(cfg),
+		}, true, nil
+	default:
+		return nil, false, fmt.Errorf("unsupported secret manager backend %q", cfg.Backend)
+	}
+}
+
+// vaultSecretFetchScript logs into Vault's Kubernetes auth method as VAULT_ROLE ($2),
+// using the pod's own service account token, then fetches each KV v2 path passed as a
+// positional argument (after MOUNT_PATH as $1) and writes the raw `vault kv get` JSON
+// response (the secret's data map under .data.data, alongside version metadata) to a
+// same-named file under MOUNT_PATH, so the caller doesn't need a JSON processor like jq
+// on the PATH, which the default Vault image doesn't ship. Secret paths are passed as
+// argv, not interpolated into the script text, so they can't be used for shell command
+// injection.
+const vaultSecretFetchScript = `set -e
+MOUNT_PATH="$1"
+VAULT_ROLE="$2"
+shift 2
+VAULT_TOKEN=$(vault write -field=token auth/kubernetes/login role="$VAULT_ROLE" jwt=@/var/run/secrets/kubernetes.io/serviceaccount/token)
+export VAULT_TOKEN
+for path in "$@"; do
+	name=$(basename "$path")
+	vault kv get -format=json "$path" > "$MOUNT_PATH/$name"
+done
+`
+
+// vaultSecretManagerFetchCommand builds the argv for the Vault init container: a fixed
+// shell script plus cfg.MountPath, cfg.VaultRole and cfg.SecretIDs passed as separate
+// arguments.
+func vaultSecretManagerFetchCommand(cfg *SecretManagerConfig) []string {
+	command := []string{"/bin/sh", "-c", vaultSecretFetchScript, "vault-secrets-init", cfg.MountPath, cfg.VaultRole}
+	return append(command, cfg.SecretIDs...)
+}
+
+// awsSecretFetchScript is a shell loop that fetches each secret ID passed as a
+// positional argument (after MOUNT_PATH as $1) via the AWS CLI and writes it to a
+// same-named file under MOUNT_PATH. Secret IDs are passed as argv, not interpolated
+// into the script text, so they can't be used for shell command injection.
+const awsSecretFetchScript = `set -e
+MOUNT_PATH="$1"
+shift
+for id in "$@"; do
+	name=$(basename "$id")
+	aws secretsmanager get-secret-value --secret-id "$id" --query SecretString --output text > "$MOUNT_PATH/$name"
+done
+`
+
+// awsSecretManagerFetchCommand builds the argv for the AWS Secrets Manager init
+// container: a fixed shell script plus cfg.MountPath and cfg.SecretIDs passed as
+// separate arguments.
+func awsSecretManagerFetchCommand(cfg *SecretManagerConfig) []string {
+	command := []string{"/bin/sh", "-c", awsSecretFetchScript, "aws-secrets-init", cfg.MountPath}
+	return append(command, cfg.SecretIDs...)
+}
+
+// gcpSecretFetchScript is a shell loop that fetches each secret ID passed as a
+// positional argument via `gcloud secrets versions access`, after MOUNT_PATH ($1) and
+// the optional GCP service account key secret ID ($2), and writes each to a file under
+// MOUNT_PATH. Secret IDs are passed as argv, not interpolated into the script text, so
+// they can't be used for shell command injection.
+const gcpSecretFetchScript = `set -e
+MOUNT_PATH="$1"
+KEY_SECRET_ID="$2"
+shift 2
+for id in "$@"; do
+	name=$(basename "$id")
+	gcloud secrets versions access latest --secret="$id" > "$MOUNT_PATH/$name"
+done
+if [ -n "$KEY_SECRET_ID" ]; then
+	gcloud secrets versions access latest --secret="$KEY_SECRET_ID" > "$MOUNT_PATH/` + gcpSecretManagerKeyFileName + `"
+fi
+`
+
+// gcpSecretManagerFetchCommand builds the argv for the GCP Secret Manager init
+// container: a fixed shell script plus cfg.MountPath, cfg.GCPServiceAccountKeySecretID
+// and cfg.SecretIDs passed as separate arguments.
+func gcpSecretManagerFetchCommand(cfg *SecretManagerConfig) []string {
+	command := []string{"/bin/sh", "-c", gcpSecretFetchScript, "gcp-secrets-init", cfg.MountPath, cfg.GCPServiceAccountKeySecretID}
+	return append(command, cfg.SecretIDs...)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/spark-on-k8s-operator/pkg/config"
+)
+
+func TestFindSecretManagerConfig(t *testing.T) {
+	_, found := FindSecretManagerConfig(map[string]string{})
+	assert.False(t, found)
+
+	annotations := map[string]string{
+		config.SecretManagerBackendAnnotation:   "aws-sm",
+		config.SecretManagerVaultRoleAnnotation: "spark",
+		config.SecretManagerSecretIDsAnnotation: "db-password, , api-key",
+	}
+	cfg, found := FindSecretManagerConfig(annotations)
+	assert.True(t, found)
+	assert.Equal(t, AWSSecretManagerBackend, cfg.Backend)
+	assert.Equal(t, "spark", cfg.VaultRole)
+	assert.Equal(t, []string{"db-password", "api-key"}, cfg.SecretIDs)
+	assert.Equal(t, DefaultSecretManagerMountPath, cfg.MountPath)
+}
+
+func TestFindSecretManagerConfig_MountPathOverride(t *testing.T) {
+	annotations := map[string]string{
+		config.SecretManagerBackendAnnotation:   "gcp-sm",
+		config.SecretManagerMountPathAnnotation: "/custom/path",
+	}
+	cfg, found := FindSecretManagerConfig(annotations)
+	assert.True(t, found)
+	assert.Equal(t, "/custom/path", cfg.MountPath)
+}
+
+func TestBuildSecretManagerContainer_CommandNonEmptyForEveryBackend(t *testing.T) {
+	for _, backend := range []SecretManagerBackend{VaultSecretManager, AWSSecretManagerBackend, GCPSecretManagerBackend} {
+		cfg := &SecretManagerConfig{
+			Backend:   backend,
+			MountPath: DefaultSecretManagerMountPath,
+			SecretIDs: []string{"some-secret"},
+			VaultRole: "spark",
+		}
+
+		container, asInit, err := buildSecretManagerContainer(cfg)
+		assert.NoError(t, err)
+		assert.True(t, asInit)
+		assert.NotEmpty(t, container.Command, "backend %s must actually fetch secrets, not just set env vars", backend)
+	}
+}
+
+func TestBuildSecretManagerContainer_UnsupportedBackend(t *testing.T) {
+	_, _, err := buildSecretManagerContainer(&SecretManagerConfig{Backend: "unsupported"})
+	assert.Error(t, err)
+}
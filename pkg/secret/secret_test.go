@@ -0,0 +1,66 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+)
+
+func TestAddSecretVolumeToPod(t *testing.T) {
+	pod := &v1.Pod{}
+
+	AddSecretVolumeToPod("test-volume", "test-secret", pod)
+	assert.Len(t, pod.Spec.Volumes, 1)
+	assert.Equal(t, "test-volume", pod.Spec.Volumes[0].Name)
+	assert.Equal(t, "test-secret", pod.Spec.Volumes[0].Secret.SecretName)
+}
+
+func TestAddSecretVolumeToPod_DoubleInvocationIsIdempotent(t *testing.T) {
+	pod := &v1.Pod{}
+
+	AddSecretVolumeToPod("test-volume", "test-secret", pod)
+	AddSecretVolumeToPod("test-volume", "test-secret", pod)
+	assert.Len(t, pod.Spec.Volumes, 1)
+}
+
+func TestMountSecretToContainer(t *testing.T) {
+	container := &v1.Container{}
+
+	MountSecretToContainer("test-volume", "/mnt/secret", container)
+	assert.Len(t, container.VolumeMounts, 1)
+	assert.Equal(t, "test-volume", container.VolumeMounts[0].Name)
+	assert.Equal(t, "/mnt/secret", container.VolumeMounts[0].MountPath)
+}
+
+func TestMountSecretToContainer_DoubleInvocationIsIdempotent(t *testing.T) {
+	container := &v1.Container{}
+
+	MountSecretToContainer("test-volume", "/mnt/secret", container)
+	MountSecretToContainer("test-volume", "/mnt/secret", container)
+	assert.Len(t, container.VolumeMounts, 1)
+}
+
+func TestMountSecretToContainer_DifferentVolumeNamesBothMount(t *testing.T) {
+	container := &v1.Container{}
+
+	MountSecretToContainer("test-volume-1", "/mnt/secret-1", container)
+	MountSecretToContainer("test-volume-2", "/mnt/secret-2", container)
+	assert.Len(t, container.VolumeMounts, 2)
+}
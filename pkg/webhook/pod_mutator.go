@@ -0,0 +1,85 @@
+/*
+Copyright 2017 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook wires the pod mutations in pkg/secret into the annotations found on a
+// SparkApplication's driver/executor pods. The mutating admission webhook server calls
+// MutateSparkPod for every pod it admits.
+package webhook
+
+import (
+	"k8s.io/api/core/v1"
+
+	"k8s.io/spark-on-k8s-operator/pkg/secret"
+)
+
+// MutateSparkPod applies every secret- and credential-related mutation this package knows
+// about to pod, based on annotations, so a SparkApplication's driver/executor pods
+// authenticate to their configured secret sources without users hand-wiring env vars or
+// volumes themselves. gcloudAuthInitImage is the operator's --gcloud-auth-init-image flag
+// value, used when sparkoperator.k8s.io/gcp-activate-service-account is set; pass "" to
+// fall back to secret.DefaultGCloudAuthInitImage.
+func MutateSparkPod(pod *v1.Pod, annotations map[string]string, gcloudAuthInitImage string) error {
+	mutateCloudCredentials(pod, annotations)
+	if err := secret.InjectSecretManagerSidecar(pod, annotations); err != nil {
+		return err
+	}
+	mutateProjectedServiceAccountTokens(pod, annotations)
+	mutateGCloudAuthInitContainer(pod, annotations, gcloudAuthInitImage)
+	return nil
+}
+
+// mutateGCloudAuthInitContainer adds a gcloud-auth-init container to pod, for each
+// container, when both a GCP service account secret and
+// sparkoperator.k8s.io/gcp-activate-service-account=true are present in annotations.
+func mutateGCloudAuthInitContainer(pod *v1.Pod, annotations map[string]string, gcloudAuthInitImage string) {
+	if !secret.ShouldActivateGCloudServiceAccount(annotations) {
+		return
+	}
+
+	secretName, mountPath, found := secret.FindGCPServiceAccountSecret(annotations)
+	if !found {
+		return
+	}
+
+	for i := range pod.Spec.Containers {
+		secret.InjectGCloudAuthInitContainer(pod, &pod.Spec.Containers[i], secretName, mountPath, gcloudAuthInitImage)
+	}
+}
+
+// mutateProjectedServiceAccountTokens adds a projected service account token volume for
+// every sparkoperator.k8s.io/projected-sa-token.<audience> annotation found.
+func mutateProjectedServiceAccountTokens(pod *v1.Pod, annotations map[string]string) {
+	for audience, mountPath := range secret.FindProjectedServiceAccountTokens(annotations) {
+		secret.AddProjectedServiceAccountTokenToPod(pod, audience, secret.DefaultProjectedServiceAccountTokenExpirationSeconds, mountPath)
+	}
+}
+
+// mutateCloudCredentials consults every secret.CloudCredentialInjectors entry and, for
+// each whose annotation is present, mounts its secret volume once on pod and wires it into
+// every container.
+func mutateCloudCredentials(pod *v1.Pod, annotations map[string]string) {
+	for _, injector := range secret.CloudCredentialInjectors {
+		secretName, mountPath, found := injector.FindSecret(annotations)
+		if !found {
+			continue
+		}
+
+		secret.AddSecretVolumeToPod(injector.VolumeName(), secretName, pod)
+		for i := range pod.Spec.Containers {
+			injector.Inject(injector.VolumeName(), mountPath, &pod.Spec.Containers[i])
+		}
+	}
+}